@@ -0,0 +1,195 @@
+// cmd/config.go
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar/layout"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix is the prefix used for every environment-variable
+// override, e.g. UG_USER, UG_OUTPUTS.
+const envPrefix = "UG_"
+
+// Collection maps a named playlist/tag filter to its own output
+// subdirectory, so a single config file can fan one account's saved
+// tabs out across several libraries.
+type Collection struct {
+	Filter string `yaml:"filter"`
+	Output string `yaml:"output"`
+}
+
+// Config is the fully resolved set of settings for a run, merged in
+// precedence order: CLI flags > environment variables > config file >
+// built-in defaults.
+type Config struct {
+	User        string                `yaml:"user"`
+	Password    string                `yaml:"password"`
+	Outputs     []string              `yaml:"outputs"`
+	Layout      string                `yaml:"layout"`
+	Formats     []string              `yaml:"formats"`
+	Concurrency int                   `yaml:"concurrency"`
+	RateLimitMS int                   `yaml:"rate_limit_ms"`
+	Collections map[string]Collection `yaml:"collections"`
+}
+
+// defaultConfig returns the built-in defaults, before any file, env,
+// or flag overrides are applied.
+func defaultConfig() Config {
+	return Config{
+		Outputs:     []string{"./out"},
+		Layout:      layout.Default,
+		Formats:     []string{defaultFormat},
+		Concurrency: 0, // 0 means runtime.NumCPU(), resolved by callers
+		RateLimitMS: 100,
+	}
+}
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/ug-scraper/config.yaml,
+// falling back to $HOME/.config/ug-scraper/config.yaml.
+func defaultConfigPath() string {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "ug-scraper", "config.yaml")
+}
+
+// LoadConfig resolves a Config for the current command invocation: it
+// starts from defaultConfig, layers in a config file (--config, or
+// the XDG default if present), then environment variables prefixed
+// UG_, then explicit CLI flags, each layer overriding the last.
+func LoadConfig(c *cli.Context) (Config, error) {
+	cfg := defaultConfig()
+
+	path := c.String("config")
+	explicit := path != ""
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	if path != "" {
+		if err := mergeConfigFile(&cfg, path, explicit); err != nil {
+			return cfg, err
+		}
+	}
+
+	mergeConfigEnv(&cfg)
+	mergeConfigFlags(c, &cfg)
+
+	return cfg, nil
+}
+
+// mergeConfigFile layers the contents of the YAML file at path onto
+// cfg. A missing file at the default path is not an error; a missing
+// file at an explicitly-requested path is.
+func mergeConfigFile(cfg *Config, path string, explicit bool) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil
+		}
+		return fmt.Errorf("reading config file %s: %w", path, err)
+	}
+
+	var fromFile Config
+	if err := yaml.Unmarshal(raw, &fromFile); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	if fromFile.User != "" {
+		cfg.User = fromFile.User
+	}
+	if fromFile.Password != "" {
+		cfg.Password = fromFile.Password
+	}
+	if len(fromFile.Outputs) > 0 {
+		cfg.Outputs = fromFile.Outputs
+	}
+	if fromFile.Layout != "" {
+		cfg.Layout = fromFile.Layout
+	}
+	if len(fromFile.Formats) > 0 {
+		cfg.Formats = fromFile.Formats
+	}
+	if fromFile.Concurrency != 0 {
+		cfg.Concurrency = fromFile.Concurrency
+	}
+	if fromFile.RateLimitMS != 0 {
+		cfg.RateLimitMS = fromFile.RateLimitMS
+	}
+	if len(fromFile.Collections) > 0 {
+		cfg.Collections = fromFile.Collections
+	}
+	return nil
+}
+
+// mergeConfigEnv layers UG_-prefixed environment variables onto cfg.
+func mergeConfigEnv(cfg *Config) {
+	if v := os.Getenv(envPrefix + "USER"); v != "" {
+		cfg.User = v
+	}
+	if v := os.Getenv(envPrefix + "PASSWORD"); v != "" {
+		cfg.Password = v
+	}
+	if v := os.Getenv(envPrefix + "OUTPUTS"); v != "" {
+		cfg.Outputs = strings.Split(v, ",")
+	}
+	if v := os.Getenv(envPrefix + "LAYOUT"); v != "" {
+		cfg.Layout = v
+	}
+	if v := os.Getenv(envPrefix + "FORMATS"); v != "" {
+		cfg.Formats = strings.Split(v, ",")
+	}
+	if v := os.Getenv(envPrefix + "CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Concurrency = n
+		}
+	}
+	if v := os.Getenv(envPrefix + "RATE_LIMIT_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.RateLimitMS = n
+		}
+	}
+}
+
+// mergeConfigFlags layers explicitly-set CLI flags onto cfg. Flags
+// win over every other layer.
+func mergeConfigFlags(c *cli.Context, cfg *Config) {
+	if c.IsSet("user") {
+		cfg.User = c.String("user")
+	}
+	if slice := c.StringSlice("output"); len(slice) > 0 {
+		cfg.Outputs = slice
+	} else if c.IsSet("output") {
+		cfg.Outputs = []string{c.String("output")}
+	}
+	if c.IsSet("layout") {
+		cfg.Layout = c.String("layout")
+	}
+	if c.IsSet("formats") {
+		cfg.Formats = c.StringSlice("formats")
+	} else if c.IsSet("format") {
+		cfg.Formats = []string{c.String("format")}
+	}
+	if c.IsSet("workers") {
+		cfg.Concurrency = c.Int("workers")
+	}
+}
+
+// configFlag is the --config flag shared by the get_all and
+// count_chords subcommands; login and logout do not take it and do
+// not load a Config.
+var configFlag = cli.StringFlag{
+	Name:  "config",
+	Usage: "--config {path.yaml}. Default '$XDG_CONFIG_HOME/ug-scraper/config.yaml'",
+}
@@ -2,212 +2,324 @@
 package cmd
 
 import (
-	"bufio"
-	"fmt"
-	"log"
-	"os"
-	"path/filepath"
-	"regexp"
-	"sort"
-	"strings"
-
-	"github.com/urfave/cli"
+    "bufio"
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "log"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+
+    "github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar/chord"
+    "github.com/urfave/cli"
 )
 
 // CountChords is the CLI command to count chord occurrences across all songs
 var CountChords = cli.Command{
-	Name:        "count_chords",
-	Usage:       "Counts the appearance and frequency of chords in all songs",
-	Description: "Analyzes all song files in the specified output directory and generates a statistics report of chord usage.",
-	Aliases:     []string{"cc"},
-	Flags: []cli.Flag{
-		cli.StringFlag{
-			Name:  "input",
-			Usage: "--input {input directory}. Default './out'",
-		},
-		cli.StringFlag{
-			Name:  "output",
-			Usage: "--output {output file path}. Default './chord_stats.txt'",
-		},
-		cli.BoolFlag{
-			Name:  "debug",
-			Usage: "Enable debug logging",
-		},
-	},
-	Action: CountChordsAction,
+    Name:        "count_chords",
+    Usage:       "Counts the appearance and frequency of chords in all songs",
+    Description: "Analyzes all song files in the specified output directory and generates a statistics report of chord usage, estimated keys, and chord-transition bigrams.",
+    Aliases:     []string{"cc"},
+    Flags: []cli.Flag{
+        cli.StringFlag{
+            Name:  "input",
+            Usage: "--input {input directory}. Default './out'",
+        },
+        cli.StringFlag{
+            Name:  "output",
+            Usage: "--output {output file path}. Default './chord_stats.txt'",
+        },
+        cli.StringFlag{
+            Name:  "format",
+            Usage: "--format {text|json|csv}. Default 'text'",
+        },
+        cli.BoolFlag{
+            Name:  "debug",
+            Usage: "Enable debug logging",
+        },
+        configFlag,
+    },
+    Action: CountChordsAction,
+}
+
+// chordFileExtensions are the exported formats known to preserve
+// chords as bracketed tokens ("[Cmaj7]"), which is what lets us parse
+// real chords instead of guessing from free text.
+var chordFileExtensions = map[string]bool{
+    ".cho":    true, // chordpro
+    ".onsong": true,
+}
+
+// bracketRegex finds bracketed tokens within a line, e.g. "[Cmaj7]"
+// in "[Cmaj7]Here comes the sun".
+var bracketRegex = regexp.MustCompile(`\[([^\]]+)\]`)
+
+// recognizedExtensions lists chordFileExtensions for the zero-files
+// warning, so it's clear which --formats count_chords can actually see.
+func recognizedExtensions() string {
+    exts := make([]string, 0, len(chordFileExtensions))
+    for ext := range chordFileExtensions {
+        exts = append(exts, ext)
+    }
+    sort.Strings(exts)
+    return strings.Join(exts, ", ")
+}
+
+// songReport is one song's chord statistics.
+type songReport struct {
+    File string         `json:"file"`
+    Key  string         `json:"key"`
+    Keys map[string]int `json:"chords"`
 }
 
 // CountChordsAction is the action function for the count_chords command
 func CountChordsAction(c *cli.Context) {
-	if c.Bool("debug") {
-		log.SetFlags(log.LstdFlags | log.Lshortfile)
-	}
-
-	inputDir := "./out"
-	if c.IsSet("input") {
-		inputDir = c.String("input")
-	}
-
-	outputFile := "./chord_stats.txt"
-	if c.IsSet("output") {
-		outputFile = c.String("output")
-	}
-
-	chordCounts := make(map[string]int)
-
-	// Improved regular expression to match a wide range of chords
-	// Matches chords like C, Cm, Cmaj7, Cadd9, C#, Db, Bm/D, etc.
-	chordRegex := regexp.MustCompile(`\b[A-G](?:#|b)?(?:m|maj7|add9|sus4|dim|aug|7|9|11|13|maj|m7|m9|aug7|dim7)?(?:/[A-G](?:#|b)?)?\b`)
-
-	// Regular expression to detect tablature lines
-	tablatureRegex := regexp.MustCompile(`^[eBgdAE]\|`)
-
-	// Iterate over all files in the input directory
-	err := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			log.Printf("Error accessing path %s: %v", path, err)
-			return err
-		}
-
-		// Skip directories
-		if info.IsDir() {
-			return nil
-		}
-
-		// Only process .crd files (assuming song files have .crd extension)
-		if strings.ToLower(filepath.Ext(path)) != ".crd" {
-			return nil
-		}
-
-		file, err := os.Open(path)
-		if err != nil {
-			log.Printf("Error opening file %s: %v", path, err)
-			return err
-		}
-		defer file.Close()
-
-		scanner := bufio.NewScanner(file)
-		lineNumber := 0
-		for scanner.Scan() {
-			line := scanner.Text()
-			lineNumber++
-
-			// Skip metadata lines
-			if strings.HasPrefix(line, "{") || strings.HasPrefix(line, "[") {
-				continue
-			}
-
-			// Skip tablature lines
-			if tablatureRegex.MatchString(line) {
-				continue
-			}
-
-			// Find all chord matches in the line
-			matches := chordRegex.FindAllString(line, -1)
-			for _, chord := range matches {
-				normalizedChord := normalizeChord(chord)
-				if normalizedChord != "" {
-					chordCounts[normalizedChord]++
-				}
-			}
-		}
-
-		if err := scanner.Err(); err != nil {
-			log.Printf("Error reading file %s: %v", path, err)
-			return err
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		log.Fatalf("Error walking through input directory: %v", err)
-	}
-
-	// Sort chords by frequency
-	type chordFrequency struct {
-		Chord string
-		Count int
-	}
-
-	var frequencies []chordFrequency
-	for chord, count := range chordCounts {
-		frequencies = append(frequencies, chordFrequency{Chord: chord, Count: count})
-	}
-
-	sort.Slice(frequencies, func(i, j int) bool {
-		return frequencies[i].Count > frequencies[j].Count
-	})
-
-	// Prepare the output
-	outputLines := []string{
-		"Chord Usage Statistics",
-		"======================",
-	}
-	for _, freq := range frequencies {
-		line := fmt.Sprintf("%s: %d", freq.Chord, freq.Count)
-		outputLines = append(outputLines, line)
-	}
-
-	// Write to the output file
-	err = os.WriteFile(outputFile, []byte(strings.Join(outputLines, "\n")), 0644)
-	if err != nil {
-		log.Fatalf("Error writing to output file %s: %v", outputFile, err)
-	}
-
-	fmt.Printf("Chord statistics written to %s\n", outputFile)
+    if c.Bool("debug") {
+        log.SetFlags(log.LstdFlags | log.Lshortfile)
+    }
+
+    cfg, err := LoadConfig(c)
+    if err != nil {
+        log.Fatalf("Error loading config: %v", err)
+    }
+
+    inputDir := "./out"
+    // This command's --output names the report file, which
+    // mergeConfigFlags has also copied into cfg.Outputs; don't read it
+    // back as the input directory.
+    if len(cfg.Outputs) > 0 && !c.IsSet("output") {
+        inputDir = cfg.Outputs[0]
+    }
+    if c.IsSet("input") {
+        inputDir = c.String("input")
+    }
+
+    outputFile := "./chord_stats.txt"
+    if c.IsSet("output") {
+        outputFile = c.String("output")
+    }
+
+    format := "text"
+    if c.IsSet("format") {
+        format = c.String("format")
+    }
+
+    globalCounts := make(map[string]int)
+    var globalHistogram [12]float64
+    bigrams := chord.NewBigramCounter()
+    var songs []songReport
+
+    err = filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
+        if err != nil {
+            log.Printf("Error accessing path %s: %v", path, err)
+            return err
+        }
+        if info.IsDir() {
+            return nil
+        }
+        if !chordFileExtensions[strings.ToLower(filepath.Ext(path))] {
+            return nil
+        }
+
+        report, err := analyzeSongFile(path, globalCounts, &globalHistogram, bigrams)
+        if err != nil {
+            log.Printf("Error analyzing file %s: %v", path, err)
+            return nil
+        }
+        songs = append(songs, report)
+        return nil
+    })
+    if err != nil {
+        log.Fatalf("Error walking through input directory: %v", err)
+    }
+    if len(songs) == 0 {
+        log.Printf("Warning: found no files with a recognized extension (%s) under %s; the report will be empty. If the library was exported with --formats, pass a matching --input or re-export with chordpro/onsong.", recognizedExtensions(), inputDir)
+    }
+
+    globalKey := chord.EstimateKey(globalHistogram)
+
+    var out []byte
+    switch format {
+    case "json":
+        out, err = renderJSON(globalCounts, globalKey, songs, bigrams)
+    case "csv":
+        out, err = renderCSV(globalCounts)
+    case "text":
+        out, err = renderText(globalCounts, globalKey, songs, bigrams)
+    default:
+        log.Fatalf("Unknown format %q. Expected text, json, or csv.", format)
+    }
+    if err != nil {
+        log.Fatalf("Error rendering report: %v", err)
+    }
+
+    if err := os.WriteFile(outputFile, out, 0644); err != nil {
+        log.Fatalf("Error writing to output file %s: %v", outputFile, err)
+    }
+
+    fmt.Printf("Chord statistics written to %s\n", outputFile)
+}
+
+// analyzeSongFile scans a single exported song file for bracketed
+// chord tokens, updating the shared global counters and returning
+// that song's own report.
+func analyzeSongFile(path string, globalCounts map[string]int, globalHistogram *[12]float64, bigrams *chord.BigramCounter) (songReport, error) {
+    report := songReport{File: path, Keys: make(map[string]int)}
+    var histogram [12]float64
+
+    file, err := os.Open(path)
+    if err != nil {
+        return report, err
+    }
+    defer file.Close()
+
+    bigrams.Reset()
+
+    scanner := bufio.NewScanner(file)
+    inTab := false
+    for scanner.Scan() {
+        line := scanner.Text()
+
+        switch strings.TrimSpace(line) {
+        case "{start_of_tab}":
+            inTab = true
+            continue
+        case "{end_of_tab}":
+            inTab = false
+            continue
+        }
+        if inTab || strings.HasPrefix(strings.TrimSpace(line), "{") {
+            continue
+        }
+
+        for _, m := range bracketRegex.FindAllStringSubmatch(line, -1) {
+            c, ok := chord.Parse(m[1])
+            if !ok {
+                continue
+            }
+            name := c.String()
+            report.Keys[name]++
+            globalCounts[name]++
+            histogram[c.PitchClass()]++
+            globalHistogram[c.PitchClass()]++
+            bigrams.Add(name)
+        }
+    }
+    if err := scanner.Err(); err != nil {
+        return report, err
+    }
+
+    report.Key = chord.EstimateKey(histogram).Name()
+    return report, nil
+}
+
+func renderText(counts map[string]int, key chord.KeyEstimate, songs []songReport, bigrams *chord.BigramCounter) ([]byte, error) {
+    var b strings.Builder
+    b.WriteString("Chord Usage Statistics\n")
+    b.WriteString("======================\n")
+    for _, freq := range sortedCounts(counts) {
+        fmt.Fprintf(&b, "%s: %d\n", freq.Chord, freq.Count)
+    }
+
+    fmt.Fprintf(&b, "\nEstimated overall key: %s (r=%.3f)\n", key.Name(), key.Correlation)
+
+    b.WriteString("\nPer-song keys\n")
+    b.WriteString("=============\n")
+    for _, song := range songs {
+        fmt.Fprintf(&b, "%s: %s\n", song.File, song.Key)
+    }
+
+    b.WriteString("\nChord transitions\n")
+    b.WriteString("=================\n")
+    for _, t := range sortedTransitions(bigrams.Counts()) {
+        fmt.Fprintf(&b, "%s -> %s: %d\n", t.Transition.From, t.Transition.To, t.Count)
+    }
+
+    return []byte(b.String()), nil
+}
+
+func renderCSV(counts map[string]int) ([]byte, error) {
+    var b strings.Builder
+    w := csv.NewWriter(&b)
+    if err := w.Write([]string{"chord", "count"}); err != nil {
+        return nil, err
+    }
+    for _, freq := range sortedCounts(counts) {
+        if err := w.Write([]string{freq.Chord, fmt.Sprintf("%d", freq.Count)}); err != nil {
+            return nil, err
+        }
+    }
+    w.Flush()
+    return []byte(b.String()), w.Error()
+}
+
+type jsonReport struct {
+    Chords        map[string]int   `json:"chords"`
+    Key           string           `json:"key"`
+    KeyConfidence float64          `json:"key_confidence"`
+    Songs         []songReport     `json:"songs"`
+    Transitions   []jsonTransition `json:"transitions"`
+}
+
+type jsonTransition struct {
+    From  string `json:"from"`
+    To    string `json:"to"`
+    Count int    `json:"count"`
+}
+
+func renderJSON(counts map[string]int, key chord.KeyEstimate, songs []songReport, bigrams *chord.BigramCounter) ([]byte, error) {
+    report := jsonReport{
+        Chords:        counts,
+        Key:           key.Name(),
+        KeyConfidence: key.Correlation,
+        Songs:         songs,
+    }
+    for _, t := range sortedTransitions(bigrams.Counts()) {
+        report.Transitions = append(report.Transitions, jsonTransition{From: t.Transition.From, To: t.Transition.To, Count: t.Count})
+    }
+    return json.MarshalIndent(report, "", "  ")
+}
+
+type chordFrequency struct {
+    Chord string
+    Count int
+}
+
+func sortedCounts(counts map[string]int) []chordFrequency {
+    frequencies := make([]chordFrequency, 0, len(counts))
+    for chordName, count := range counts {
+        frequencies = append(frequencies, chordFrequency{Chord: chordName, Count: count})
+    }
+    sort.Slice(frequencies, func(i, j int) bool {
+        if frequencies[i].Count != frequencies[j].Count {
+            return frequencies[i].Count > frequencies[j].Count
+        }
+        return frequencies[i].Chord < frequencies[j].Chord
+    })
+    return frequencies
 }
 
-// normalizeChord standardizes chord notation for consistent counting
-func normalizeChord(chord string) string {
-	// Remove any surrounding whitespace and convert to proper case
-	chord = strings.TrimSpace(chord)
-	if chord == "" {
-		return ""
-	}
-
-	// Split chord and inversion if present
-	var chordPart, inversionPart string
-	if strings.Contains(chord, "/") {
-		parts := strings.Split(chord, "/")
-		chordPart = parts[0]
-		inversionPart = parts[1]
-	} else {
-		chordPart = chord
-	}
-
-	// Normalize chord part
-	chordPart = normalizeChordPart(chordPart)
-
-	// Normalize inversion part if present
-	if inversionPart != "" {
-		inversionPart = normalizeChordPart(inversionPart)
-		return chordPart + "/" + inversionPart
-	}
-	return chordPart
+type transitionCount struct {
+    Transition chord.Transition
+    Count      int
 }
 
-// normalizeChordPart formats the chord part correctly
-func normalizeChordPart(chord string) string {
-	if len(chord) == 0 {
-		return ""
-	}
-
-	// Capitalize the first letter and handle the rest
-	chord = strings.ToUpper(string(chord[0])) + chord[1:]
-
-	// Convert minor indicators to lowercase 'm'
-	chord = strings.ReplaceAll(chord, "M", "m")
-	// Handle specific chord suffixes
-	suffixes := []string{"Maj", "Maj7", "m7", "m9", "m11", "m13", "add9", "sus4", "dim", "dim7", "aug", "aug7", "7", "9", "11", "13"}
-
-	for _, suffix := range suffixes {
-		if strings.HasSuffix(chord, suffix) {
-			index := strings.LastIndex(chord, suffix)
-			chord = chord[:index] + suffix
-			break
-		}
-	}
-
-	return chord
+func sortedTransitions(counts map[chord.Transition]int) []transitionCount {
+    transitions := make([]transitionCount, 0, len(counts))
+    for t, count := range counts {
+        transitions = append(transitions, transitionCount{Transition: t, Count: count})
+    }
+    sort.Slice(transitions, func(i, j int) bool {
+        if transitions[i].Count != transitions[j].Count {
+            return transitions[i].Count > transitions[j].Count
+        }
+        if transitions[i].Transition.From != transitions[j].Transition.From {
+            return transitions[i].Transition.From < transitions[j].Transition.From
+        }
+        return transitions[i].Transition.To < transitions[j].Transition.To
+    })
+    return transitions
 }
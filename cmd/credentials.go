@@ -0,0 +1,57 @@
+// cmd/credentials.go
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService namespaces every credential this tool stores in the
+// OS keyring, so it doesn't collide with unrelated applications.
+const keyringService = "ultimate-guitar-scraper"
+
+// storeCredential saves password in the OS keyring under user, so
+// later runs can skip the interactive prompt.
+func storeCredential(user, password string) error {
+	if err := keyring.Set(keyringService, user, password); err != nil {
+		return fmt.Errorf("storing credential in keyring: %w", err)
+	}
+	return nil
+}
+
+// lookupCredential returns the keyring-stored password for user, if
+// any. ok is false (with a nil error) when nothing is stored yet.
+func lookupCredential(user string) (password string, ok bool, err error) {
+	password, err = keyring.Get(keyringService, user)
+	if err == keyring.ErrNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("reading credential from keyring: %w", err)
+	}
+	return password, true, nil
+}
+
+// deleteCredential removes the keyring-stored password for user, if
+// any.
+func deleteCredential(user string) error {
+	err := keyring.Delete(keyringService, user)
+	if err != nil && err != keyring.ErrNotFound {
+		return fmt.Errorf("deleting credential from keyring: %w", err)
+	}
+	return nil
+}
+
+// readTokenFile reads and trims a password/token from path, for CI
+// environments where neither an interactive prompt nor the OS
+// keyring is available.
+func readTokenFile(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading token file %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
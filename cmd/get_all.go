@@ -2,20 +2,46 @@ package cmd
 
 import (
     "bufio"
+    "bytes"
+    "encoding/json"
     "errors"
     "fmt"
+    "hash/fnv"
     "log"
+    "net/http"
     "os"
     "path/filepath"
     "regexp"
+    "runtime"
+    "strconv"
     "strings"
     "syscall"
+    "time"
 
     "github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+    "github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar/export"
+    "github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar/filter"
+    "github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar/index"
+    "github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar/layout"
+    "github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar/manifest"
+    usync "github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar/sync"
     "github.com/urfave/cli"
     "golang.org/x/term"
 )
 
+// defaultFormat is used when neither --format nor --formats is given.
+const defaultFormat = "chordpro"
+
+// manifestFilename is the name of the incremental-sync manifest
+// database, stored inside the primary output root.
+const manifestFilename = ".ug-scraper-manifest.db"
+
+// ugAPIEndpoint mirrors the unexported constant of the same name in
+// pkg/ultimateguitar/api.go. UG's API base URL is a fixed property of
+// their app, not something the vendored package exposes a getter for,
+// so listSavedTabs has to know it too in order to hit MY_TABS directly.
+const ugAPIEndpoint = "https://api.ultimate-guitar.com/api/v1"
+
 var GetAll = cli.Command{
     Name:        "get_all",
     Usage:       "Fetches all saved tabs/songs for Ultimate Guitar. Requires you to login.",
@@ -26,14 +52,59 @@ var GetAll = cli.Command{
             Name:  "user",
             Usage: "--user {your_email}",
         },
-        cli.StringFlag{
+        cli.StringSliceFlag{
             Name:  "output",
-            Usage: "--output {output path}. Default './out'",
+            Usage: "--output {output path}. Repeatable to partition the library across several roots (disks, per-genre libraries, ...). Default './out'",
+        },
+        cli.StringFlag{
+            Name:  "layout",
+            Usage: `--layout {template}. Path template relative to each output root, e.g. "{artist}/{artist} - {song} ({type})". Variables: artist, song, capo, tuning, type, difficulty, rating. Default "{artist} - {song}"`,
+        },
+        cli.StringFlag{
+            Name:  "format",
+            Usage: fmt.Sprintf("--format {format}. One of: %s. Default 'chordpro'", strings.Join(export.Names(), ", ")),
+        },
+        cli.StringSliceFlag{
+            Name:  "formats",
+            Usage: "--formats {format1,format2,...}. Writes every saved tab in each format; with more than one format, each goes into a per-format subdirectory.",
+        },
+        cli.StringFlag{
+            Name:  "filter-type",
+            Usage: "--filter-type {chords|tabs|bass|ukulele}. Only write tabs of this type.",
+        },
+        cli.Float64Flag{
+            Name:  "filter-min-rating",
+            Usage: "--filter-min-rating {n}. Only write tabs rated at least n.",
+        },
+        cli.StringFlag{
+            Name:  "filter-artist",
+            Usage: "--filter-artist {regex}. Only write tabs whose artist matches this regular expression.",
+        },
+        cli.StringFlag{
+            Name:  "since",
+            Usage: "--since {RFC3339 time}. Re-write tabs not seen in the manifest since this time, even if unchanged.",
+        },
+        cli.BoolFlag{
+            Name:  "force-refresh",
+            Usage: "Re-write every tab, ignoring the manifest entirely.",
+        },
+        cli.BoolFlag{
+            Name:  "prune",
+            Usage: "Remove manifest entries (and report them) for tabs no longer in your saved list.",
+        },
+        cli.IntFlag{
+            Name:  "workers",
+            Usage: "--workers {n}. Concurrent fetch workers. Default runtime.NumCPU().",
+        },
+        cli.StringFlag{
+            Name:  "token-file",
+            Usage: "--token-file {path}. Read the password/session token from a file instead of the keyring or a prompt. Intended for CI.",
         },
         cli.BoolFlag{
             Name:  "debug",
             Usage: "Enable debug logging",
         },
+        configFlag,
     },
     Action: GetAllTabs,
 }
@@ -43,12 +114,13 @@ func GetAllTabs(c *cli.Context) {
         log.SetFlags(log.LstdFlags | log.Lshortfile)
     }
 
-    var user, password string
-    var err error
+    cfg, err := LoadConfig(c)
+    if err != nil {
+        log.Fatalf("Error loading config: %v", err)
+    }
 
-    if c.IsSet("user") {
-        user = c.String("user")
-    } else {
+    user := cfg.User
+    if user == "" {
         reader := bufio.NewReader(os.Stdin)
         fmt.Print("Username: ")
         user, err = reader.ReadString('\n')
@@ -58,90 +130,383 @@ func GetAllTabs(c *cli.Context) {
         }
     }
 
-    fmt.Print("Password: ")
-    bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+    // Credential resolution order: --token-file, then --user + env/config
+    // (cfg.Password), then a keyring lookup for user, then an
+    // interactive prompt.
+    fromTokenFile := c.IsSet("token-file")
+    password := cfg.Password
+    if fromTokenFile {
+        password, err = readTokenFile(c.String("token-file"))
+        if err != nil {
+            log.Fatalf("Error reading token file: %v", err)
+        }
+    } else if password == "" {
+        if stored, ok, lookupErr := lookupCredential(user); lookupErr != nil {
+            log.Printf("Warning: keyring lookup failed: %v", lookupErr)
+        } else if ok {
+            password = stored
+        }
+    }
+    if password == "" {
+        fmt.Print("Password: ")
+        bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+        if err != nil {
+            log.Fatalf("Error reading password: %v", err)
+        }
+        fmt.Println() // Move to the next line after password input
+        password = strings.TrimSpace(string(bytePassword))
+    }
+
+    scraper, err := loginScraper(user, password)
+    if err != nil {
+        log.Fatalf("Error logging in: %v", err)
+    }
+
+    // Keyring storage isn't typically available in the CI environments
+    // --token-file targets, so don't try to persist that credential.
+    if !fromTokenFile {
+        if err := storeCredential(user, password); err != nil {
+            log.Printf("Warning: could not save credential to keyring: %v", err)
+        }
+    }
+
+    listings, err := listSavedTabs(scraper)
+    if err != nil {
+        log.Fatalf("Error listing saved tabs: %v", err)
+    }
+
+    roots := make([]string, 0, len(cfg.Outputs))
+    for _, root := range cfg.Outputs {
+        abs, err := filepath.Abs(root)
+        if err != nil {
+            log.Fatalf("Error resolving output path %s: %v", root, err)
+        }
+        if err := ensureOutputDir(abs); err != nil {
+            log.Fatalf("Error preparing output directory %s: %v", abs, err)
+        }
+        roots = append(roots, abs)
+    }
+    fmt.Println("Output roots:", strings.Join(roots, ", "))
+
+    exporters := make([]export.Exporter, 0, len(cfg.Formats))
+    for _, name := range cfg.Formats {
+        e, ok := export.Get(name)
+        if !ok {
+            log.Fatalf("Unknown format %q. Available formats: %s", name, strings.Join(export.Names(), ", "))
+        }
+        exporters = append(exporters, e)
+    }
+
+    f := buildFilter(c)
+
+    collections, err := buildCollections(cfg.Collections)
     if err != nil {
-        log.Fatalf("Error reading password: %v", err)
+        log.Fatalf("Error loading collections: %v", err)
     }
-    fmt.Println() // Move to the next line after password input
-    password = strings.TrimSpace(string(bytePassword))
 
-    tabs, err := fetchAllTabs(user, password)
+    m, err := manifest.Open(filepath.Join(roots[0], manifestFilename))
     if err != nil {
-        log.Fatalf("Error fetching tabs: %v", err)
+        log.Fatalf("Error opening manifest: %v", err)
     }
+    defer m.Close()
 
-    path := "./out/"
-    if c.IsSet("output") {
-        path = c.String("output")
+    var since time.Time
+    if c.IsSet("since") {
+        since, err = time.Parse(time.RFC3339, c.String("since"))
+        if err != nil {
+            log.Fatalf("Error parsing --since: %v", err)
+        }
     }
 
-    path, err = filepath.Abs(path)
+    plan, err := usync.DiffListings(m, listings, since, c.Bool("force-refresh"))
     if err != nil {
-        log.Fatalf("Error resolving output path: %v", err)
+        log.Fatalf("Error diffing saved tabs against manifest: %v", err)
+    }
+
+    if c.Bool("prune") {
+        for _, id := range plan.Removed {
+            if err := m.Delete(id); err != nil {
+                log.Printf("Error pruning manifest entry %s: %v", id, err)
+            }
+        }
+        if len(plan.Removed) > 0 {
+            fmt.Printf("Pruned %d manifest entries for tabs no longer saved\n", len(plan.Removed))
+        }
+    }
+
+    workers := cfg.Concurrency
+    if workers <= 0 {
+        workers = runtime.NumCPU()
+    }
+
+    fetched, fetchErrs := usync.FetchPool(plan.ToFetch, usync.PoolOptions{
+        Workers:    workers,
+        RateLimit:  time.Duration(cfg.RateLimitMS) * time.Millisecond,
+        MaxRetries: 3,
+        Backoff:    time.Second,
+    }, func(listing ultimateguitar.TabListing) (ultimateguitar.TabResult, error) {
+        return scraper.GetTabByID(int64(listing.Tab.ID))
+    })
+    for _, err := range fetchErrs {
+        log.Printf("Error fetching tab: %v", err)
     }
 
-    err = writeTabs(path, tabs)
+    var indexEntries []index.Entry
+
+    now := time.Now()
+    written := 0
+    // Collections are matched against every tab fetched this run,
+    // independent of f: f only decides what lands in the primary
+    // --output roots, so a collection with its own, broader filter
+    // still gets every tab it asks for even when f would exclude it.
+    for _, tab := range fetched {
+        var files []string
+
+        if f.Match(tab) {
+            root := pickRoot(roots, tab)
+            tabFiles, err := writeTab(root, tab, exporters, cfg.Layout)
+            if err != nil {
+                log.Printf("Error writing tab %d: %v", tab.ID, err)
+            } else {
+                files = append(files, tabFiles...)
+                written++
+
+                indexEntries = append(indexEntries, index.Entry{
+                    Artist: tab.ArtistName,
+                    Song:   tab.SongName,
+                    Root:   root,
+                    Files:  tabFiles,
+                })
+            }
+        }
+
+        for _, col := range collections {
+            if !col.filter.Match(tab) {
+                continue
+            }
+            colFiles, err := writeTab(col.root, tab, exporters, cfg.Layout)
+            if err != nil {
+                log.Printf("Error writing tab %d to collection %s: %v", tab.ID, col.name, err)
+                continue
+            }
+            files = append(files, colFiles...)
+            indexEntries = append(indexEntries, index.Entry{
+                Artist: tab.ArtistName,
+                Song:   tab.SongName,
+                Root:   col.root,
+                Files:  colFiles,
+            })
+        }
+
+        if err := usync.Record(m, tab, files, now); err != nil {
+            log.Printf("Error recording manifest entry for tab %d: %v", tab.ID, err)
+        }
+    }
+
+    // Tabs this run didn't re-fetch (unchanged since last sync) would
+    // otherwise fall out of index.json/index.m3u entirely, even though
+    // they're still part of the library. Carry their last-recorded
+    // location forward from the manifest so the index stays a full
+    // catalog, not just this run's delta.
+    fetchedIDs := make(map[string]bool, len(fetched))
+    for _, tab := range fetched {
+        fetchedIDs[strconv.Itoa(tab.ID)] = true
+    }
+    allEntries, err := m.All()
     if err != nil {
-        log.Fatalf("Error writing tabs: %v", err)
+        log.Printf("Error reading manifest for index: %v", err)
+    }
+    for id, entry := range allEntries {
+        if fetchedIDs[id] || len(entry.Files) == 0 {
+            continue
+        }
+        indexEntries = append(indexEntries, index.Entry{
+            Artist: entry.ArtistName,
+            Song:   entry.SongName,
+            Files:  entry.Files,
+        })
+    }
+
+    if err := index.WriteJSON(filepath.Join(roots[0], "index.json"), indexEntries); err != nil {
+        log.Printf("Error writing index.json: %v", err)
     }
-    fmt.Printf("Wrote %d tabs to %s\n", len(tabs), path)
+    if err := index.WriteM3U(filepath.Join(roots[0], "index.m3u"), indexEntries); err != nil {
+        log.Printf("Error writing index.m3u: %v", err)
+    }
+
+    unchanged := len(listings) - len(plan.ToFetch)
+    fmt.Printf("Wrote %d tab(s) in %d format(s) across %d root(s) (%d fetched, %d unchanged, skipped)\n",
+        written, len(exporters), len(roots), len(fetched), unchanged)
+}
+
+// buildFilter assembles a filter.Filter from the --filter-* flags.
+func buildFilter(c *cli.Context) filter.Filter {
+    f := filter.Filter{
+        Type:      c.String("filter-type"),
+        MinRating: c.Float64("filter-min-rating"),
+    }
+    if pattern := c.String("filter-artist"); pattern != "" {
+        re, err := regexp.Compile(pattern)
+        if err != nil {
+            log.Fatalf("Error compiling --filter-artist regex: %v", err)
+        }
+        f.ArtistPattern = re
+    }
+    return f
 }
 
-func fetchAllTabs(user string, password string) ([]ultimateguitar.TabResult, error) {
-    var tabResults []ultimateguitar.TabResult
+// collectionTarget is a resolved config collection: a filter deciding
+// which tabs it wants, and the absolute output directory to write
+// them to, in addition to their regular --output root.
+type collectionTarget struct {
+    name   string
+    filter filter.Filter
+    root   string
+}
+
+// buildCollections resolves the config file's collections: section
+// into collectionTargets, compiling each one's filter expression and
+// preparing its output directory.
+func buildCollections(collections map[string]Collection) ([]collectionTarget, error) {
+    targets := make([]collectionTarget, 0, len(collections))
+    for name, col := range collections {
+        f, err := filter.Parse(col.Filter)
+        if err != nil {
+            return nil, fmt.Errorf("collection %s: %w", name, err)
+        }
+        abs, err := filepath.Abs(col.Output)
+        if err != nil {
+            return nil, fmt.Errorf("collection %s: resolving output path: %w", name, err)
+        }
+        if err := ensureOutputDir(abs); err != nil {
+            return nil, fmt.Errorf("collection %s: preparing output directory: %w", name, err)
+        }
+        targets = append(targets, collectionTarget{name: name, filter: f, root: abs})
+    }
+    return targets, nil
+}
+
+// pickRoot deterministically assigns tab to one of roots, so repeat
+// runs keep placing the same tab in the same root as the library is
+// partitioned across disks or libraries.
+func pickRoot(roots []string, tab ultimateguitar.TabResult) string {
+    if len(roots) == 1 {
+        return roots[0]
+    }
+    h := fnv.New32a()
+    h.Write([]byte(strconv.Itoa(tab.ID)))
+    return roots[h.Sum32()%uint32(len(roots))]
+}
+
+// loginScraper authenticates against UG and returns the resulting
+// Scraper, ready for listSavedTabs/GetTabByID calls.
+func loginScraper(user string, password string) (*ultimateguitar.Scraper, error) {
     s := ultimateguitar.New()
     res, err := s.Login(user, password)
     if err != nil {
-        return tabResults, fmt.Errorf("login error: %w", err)
+        return nil, fmt.Errorf("login error: %w", err)
     }
     if res == "Failed to login" {
-        return tabResults, errors.New("login failed: invalid username or password")
+        return nil, errors.New("login failed: invalid username or password")
     }
-    tabResults, err = s.GetAll()
+    return &s, nil
+}
+
+// listSavedTabs fetches the lightweight "my tabs" listing directly: ID,
+// version, artist/song, but not a tab's full content. The vendored
+// ultimateguitar.Scraper only exposes this data bundled with a full
+// per-tab GetTabByID call inside GetAll, which would defeat the point
+// of diffing against the manifest before paying for a download. Since
+// GetAll fetches this same listing the same way, this mirrors that
+// request rather than inventing a new one.
+func listSavedTabs(s *ultimateguitar.Scraper) ([]ultimateguitar.TabListing, error) {
+    var listings []ultimateguitar.TabListing
+    if s.Token == "" {
+        return listings, errors.New("listSavedTabs: requires token to list saved tabs")
+    }
+
+    urlString := fmt.Sprintf("%s%s?token=%s", ugAPIEndpoint, ultimateguitar.AppPaths.MY_TABS, s.Token)
+    req, err := http.NewRequest("GET", urlString, nil)
     if err != nil {
-        return tabResults, fmt.Errorf("error fetching tabs: %w", err)
+        return listings, err
+    }
+    s.ConfigureHeaders(req)
+
+    res, err := s.Client.Do(req)
+    if err != nil {
+        return listings, err
+    }
+    defer res.Body.Close()
+
+    if err := json.NewDecoder(res.Body).Decode(&listings); err != nil {
+        return listings, fmt.Errorf("listSavedTabs: decoding response: %w", err)
     }
-    return tabResults, nil
+    return listings, nil
 }
 
-func writeTabs(path string, tabs []ultimateguitar.TabResult) error {
+// ensureOutputDir creates path if it doesn't already exist.
+func ensureOutputDir(path string) error {
     if path == "" {
-        return errors.New("writeTabs: requires path")
+        return errors.New("ensureOutputDir: requires path")
     }
     if _, err := os.Stat(path); os.IsNotExist(err) {
-        err := os.MkdirAll(path, 0775)
-        if err != nil {
+        if err := os.MkdirAll(path, 0775); err != nil {
             return fmt.Errorf("error creating output directory: %w", err)
         }
     }
+    return nil
+}
 
-    fmt.Println("Output directory:", path)
+// writeTab renders tab with every exporter and writes each result to
+// disk under root, using tmpl to lay out the path (and its format
+// extension). It writes to a temp file and renames into place so an
+// interrupted run never leaves a partial file behind. It returns the
+// paths written.
+func writeTab(root string, tab ultimateguitar.TabResult, exporters []export.Exporter, tmpl string) ([]string, error) {
+    relPath := layout.Render(tmpl, tab)
+    multi := len(exporters) > 1
 
-    for _, tab := range tabs {
-        artist := tab.ArtistName
-        songName := tab.SongName
-        capo := tab.Capo
-        content := tab.Content
-        content = fmt.Sprintf("{artist: %s}\n{title: %s}\n{capo: %d}\n%s", artist, songName, capo, content)
+    var written []string
+    for _, e := range exporters {
+        dir := root
+        if multi {
+            dir = filepath.Join(root, e.Name())
+        }
 
-        regex := regexp.MustCompile(`\[(/?tab|/?ch)\]`)
-        content = regex.ReplaceAllString(content, "")
+        filePath := filepath.Join(dir, relPath) + "." + e.Extension()
+        if err := os.MkdirAll(filepath.Dir(filePath), 0775); err != nil {
+            return written, fmt.Errorf("creating directory for %s: %w", filePath, err)
+        }
 
-        filename := fmt.Sprintf("%s-%s.crd", sanitizeFilename(artist), sanitizeFilename(songName))
-        filePath := filepath.Join(path, filename)
-        err := os.WriteFile(filePath, []byte(content), 0644)
-        if err != nil {
-            log.Printf("Error writing file %s: %v", filename, err)
-            continue
+        var buf bytes.Buffer
+        if err := e.Render(tab, &buf); err != nil {
+            return written, fmt.Errorf("rendering %s as %s: %w", relPath, e.Name(), err)
         }
+
+        if err := writeFileAtomic(filePath, buf.Bytes()); err != nil {
+            return written, fmt.Errorf("writing file %s: %w", filePath, err)
+        }
+        written = append(written, filePath)
     }
-    return nil
+    return written, nil
 }
 
-// sanitizeFilename removes or replaces characters that are invalid in filenames
-func sanitizeFilename(name string) string {
-    // Remove any characters that are not letters, numbers, spaces, hyphens, or underscores
-    regex := regexp.MustCompile(`[<>:"/\\|?*]`)
-    return regex.ReplaceAllString(name, "")
+// writeFileAtomic writes data to a temp file in the same directory
+// as path, then renames it into place, so readers never observe a
+// partially-written file.
+func writeFileAtomic(path string, data []byte) error {
+    tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), path)
 }
@@ -0,0 +1,68 @@
+// cmd/login.go
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+	"github.com/urfave/cli"
+	"golang.org/x/term"
+)
+
+// Login is the CLI command to authenticate once and store the
+// resulting credential in the OS keyring, so get_all doesn't need to
+// prompt on every run.
+var Login = cli.Command{
+	Name:        "login",
+	Usage:       "Authenticates with Ultimate Guitar and stores the credential in the OS keyring",
+	Description: "Prompts for a username and password, verifies them against Ultimate Guitar, and stores the password in the OS keyring for future get_all runs.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "user",
+			Usage: "--user {your_email}",
+		},
+	},
+	Action: LoginAction,
+}
+
+// LoginAction is the action function for the login command.
+func LoginAction(c *cli.Context) {
+	user := c.String("user")
+	var err error
+	if user == "" {
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Print("Username: ")
+		user, err = reader.ReadString('\n')
+		user = strings.TrimSpace(user)
+		if err != nil {
+			log.Fatalf("Error reading username: %v", err)
+		}
+	}
+
+	fmt.Print("Password: ")
+	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		log.Fatalf("Error reading password: %v", err)
+	}
+	fmt.Println()
+	password := strings.TrimSpace(string(bytePassword))
+
+	s := ultimateguitar.New()
+	res, err := s.Login(user, password)
+	if err != nil {
+		log.Fatalf("Login error: %v", err)
+	}
+	if res == "Failed to login" {
+		log.Fatalf("Login failed: invalid username or password")
+	}
+
+	if err := storeCredential(user, password); err != nil {
+		log.Fatalf("Login succeeded but saving to keyring failed: %v", err)
+	}
+	fmt.Printf("Logged in as %s and saved credential to the keyring.\n", user)
+}
@@ -0,0 +1,37 @@
+// cmd/logout.go
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/urfave/cli"
+)
+
+// Logout is the CLI command to remove a previously stored credential
+// from the OS keyring.
+var Logout = cli.Command{
+	Name:        "logout",
+	Usage:       "Removes a stored credential from the OS keyring",
+	Description: "Deletes the keyring entry saved by login or get_all for the given user.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "user",
+			Usage: "--user {your_email}",
+		},
+	},
+	Action: LogoutAction,
+}
+
+// LogoutAction is the action function for the logout command.
+func LogoutAction(c *cli.Context) {
+	user := c.String("user")
+	if user == "" {
+		log.Fatalf("logout requires --user")
+	}
+
+	if err := deleteCredential(user); err != nil {
+		log.Fatalf("Error clearing credential: %v", err)
+	}
+	fmt.Printf("Cleared stored credential for %s.\n", user)
+}
@@ -0,0 +1,43 @@
+package chord
+
+// Transition is an ordered pair of consecutive chords, identified by
+// their normalized string form.
+type Transition struct {
+	From string
+	To   string
+}
+
+// BigramCounter tallies chord-to-chord transitions across one or more
+// songs. Call Reset between songs so a transition isn't recorded
+// across a song boundary.
+type BigramCounter struct {
+	counts   map[Transition]int
+	prev     string
+	havePrev bool
+}
+
+// NewBigramCounter returns an empty BigramCounter.
+func NewBigramCounter() *BigramCounter {
+	return &BigramCounter{counts: make(map[Transition]int)}
+}
+
+// Add records chord as the next chord in the current sequence,
+// incrementing the transition count from the previous chord to it.
+func (b *BigramCounter) Add(chord string) {
+	if b.havePrev {
+		b.counts[Transition{From: b.prev, To: chord}]++
+	}
+	b.prev = chord
+	b.havePrev = true
+}
+
+// Reset ends the current sequence, so the next Add does not form a
+// transition with the last chord seen before the reset.
+func (b *BigramCounter) Reset() {
+	b.havePrev = false
+}
+
+// Counts returns the accumulated transition counts.
+func (b *BigramCounter) Counts() map[Transition]int {
+	return b.counts
+}
@@ -0,0 +1,229 @@
+// Package chord implements a small music-theory-aware chord parser,
+// used to tell real chord symbols ("Cmaj7", "F#m/A") apart from
+// ordinary words that happen to start with a note letter ("Am I",
+// "Be", "Dam").
+package chord
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Quality is the triad/seventh quality of a chord.
+type Quality string
+
+const (
+	QualityMajor      Quality = "maj"
+	QualityMinor      Quality = "min"
+	QualityDiminished Quality = "dim"
+	QualityAugmented  Quality = "aug"
+	QualitySus2       Quality = "sus2"
+	QualitySus4       Quality = "sus4"
+)
+
+// Chord is a fully parsed chord symbol.
+type Chord struct {
+	Root        byte     // 'A'..'G'
+	Accidental  int      // -1 flat, 0 natural, +1 sharp
+	Quality     Quality  // QualityMajor unless a minor/dim/aug/sus marker was present
+	Extensions  []string // "6", "7", "maj7", "9", "11", "13", in the order parsed
+	Alterations []string // parenthesized alterations, e.g. "b5", "#9", "add11"
+	HasBass     bool
+	BassRoot    byte // only valid when HasBass
+	BassAcc     int  // only valid when HasBass
+}
+
+// pitchClasses maps a natural note letter to its pitch class (C=0).
+var pitchClasses = map[byte]int{
+	'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11,
+}
+
+// PitchClass returns the chord's root as a pitch class in [0, 12).
+func PitchClass(root byte, accidental int) int {
+	return (pitchClasses[root] + accidental + 12) % 12
+}
+
+// PitchClass returns c's root pitch class in [0, 12).
+func (c Chord) PitchClass() int {
+	return PitchClass(c.Root, c.Accidental)
+}
+
+// qualityTokens lists the recognized quality markers, each token
+// ahead of any shorter token it starts with, so e.g. "min" is tried
+// before "m".
+var qualityTokens = []struct {
+	token   string
+	quality Quality
+}{
+	{"sus2", QualitySus2},
+	{"sus4", QualitySus4},
+	{"min", QualityMinor},
+	{"maj", QualityMajor},
+	{"M", QualityMajor},
+	{"dim", QualityDiminished},
+	{"aug", QualityAugmented},
+	{"°", QualityDiminished}, // °
+	{"+", QualityAugmented},
+	{"m", QualityMinor},
+}
+
+// extensionTokens lists recognized extension markers, longest first.
+var extensionTokens = []string{"13", "11", "9", "7", "6"}
+
+var (
+	rootRegex       = regexp.MustCompile(`^[A-G]`)
+	accidentalRegex = regexp.MustCompile(`^(#|b|♯|♭)`)
+	alterationRegex = regexp.MustCompile(`^\(([^)]*)\)`)
+	// majExtensionRegex matches an explicit major extension, "maj7" or
+	// its shorthand "M7" (also maj9/M9, maj11/M11, maj13/M13), so it
+	// can be captured as an extension before the quality loop below
+	// gets a chance to swallow the "maj"/"M" on its own and collapse a
+	// major seventh into a bare dominant seventh (e.g. "Cmaj7"
+	// matching "maj" as QualityMajor and leaving "7" to be parsed as
+	// an ordinary extension, indistinguishable from "C7").
+	majExtensionRegex = regexp.MustCompile(`^(?:maj|M)(6|7|9|11|13)`)
+)
+
+// Parse attempts to interpret s as a chord symbol. It returns
+// ok=false for anything that isn't shaped like a chord (ordinary
+// words, section labels, etc.), which is what lets CountChords skip
+// false positives instead of guessing from free text.
+func Parse(s string) (Chord, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Chord{}, false
+	}
+
+	var c Chord
+	rest := s
+
+	root := rootRegex.FindString(rest)
+	if root == "" {
+		return Chord{}, false
+	}
+	c.Root = root[0]
+	rest = rest[len(root):]
+
+	if m := accidentalRegex.FindString(rest); m != "" {
+		c.Accidental = accidentalValue(m)
+		rest = rest[len(m):]
+	}
+
+	c.Quality = QualityMajor
+	if m := majExtensionRegex.FindStringSubmatch(rest); m != nil {
+		c.Extensions = append(c.Extensions, "maj"+m[1])
+		rest = rest[len(m[0]):]
+	} else {
+		for _, qt := range qualityTokens {
+			if strings.HasPrefix(rest, qt.token) {
+				// Don't let the "m" in "maj7" be mistaken for minor; "maj"
+				// is tried first so this only matches a bare "m".
+				c.Quality = qt.quality
+				rest = rest[len(qt.token):]
+				break
+			}
+		}
+	}
+
+	for {
+		matched := false
+		for _, ext := range extensionTokens {
+			if strings.HasPrefix(rest, ext) {
+				c.Extensions = append(c.Extensions, ext)
+				rest = rest[len(ext):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			break
+		}
+	}
+
+	for {
+		m := alterationRegex.FindStringSubmatch(rest)
+		if m == nil {
+			break
+		}
+		c.Alterations = append(c.Alterations, m[1])
+		rest = rest[len(m[0]):]
+	}
+
+	if strings.HasPrefix(rest, "/") {
+		rest = rest[1:]
+		bassRoot := rootRegex.FindString(rest)
+		if bassRoot == "" {
+			return Chord{}, false
+		}
+		c.HasBass = true
+		c.BassRoot = bassRoot[0]
+		rest = rest[len(bassRoot):]
+		if m := accidentalRegex.FindString(rest); m != "" {
+			c.BassAcc = accidentalValue(m)
+			rest = rest[len(m):]
+		}
+	}
+
+	if rest != "" {
+		return Chord{}, false
+	}
+	return c, true
+}
+
+func accidentalValue(s string) int {
+	switch s {
+	case "#", "♯":
+		return 1
+	case "b", "♭":
+		return -1
+	default:
+		return 0
+	}
+}
+
+// String renders c back to conventional chord notation, e.g.
+// "C#m7(b5)/G".
+func (c Chord) String() string {
+	var b strings.Builder
+	b.WriteByte(c.Root)
+	b.WriteString(accidentalString(c.Accidental))
+	if c.Quality != QualityMajor {
+		b.WriteString(string(c.Quality))
+	}
+	for _, ext := range c.Extensions {
+		b.WriteString(ext)
+	}
+	for _, alt := range c.Alterations {
+		b.WriteByte('(')
+		b.WriteString(alt)
+		b.WriteByte(')')
+	}
+	if c.HasBass {
+		b.WriteByte('/')
+		b.WriteByte(c.BassRoot)
+		b.WriteString(accidentalString(c.BassAcc))
+	}
+	return b.String()
+}
+
+func accidentalString(acc int) string {
+	switch {
+	case acc > 0:
+		return strings.Repeat("#", acc)
+	case acc < 0:
+		return strings.Repeat("b", -acc)
+	default:
+		return ""
+	}
+}
+
+// pitchClassNames are used for reporting normalized (enharmonic-merged)
+// pitch classes back to a human-readable name, preferring sharps.
+var pitchClassNames = [12]string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+// PitchClassName returns the canonical sharp-spelled name for pc,
+// e.g. PitchClassName(1) == "C#". pc is taken mod 12.
+func PitchClassName(pc int) string {
+	pc = ((pc % 12) + 12) % 12
+	return pitchClassNames[pc]
+}
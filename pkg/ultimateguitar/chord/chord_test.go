@@ -0,0 +1,59 @@
+package chord
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input      string
+		wantOK     bool
+		wantString string
+	}{
+		{"C", true, "C"},
+		{"C7", true, "C7"},
+		{"Cmaj7", true, "Cmaj7"},
+		{"CM7", true, "Cmaj7"},
+		{"Cmaj9", true, "Cmaj9"},
+		{"CM9", true, "Cmaj9"},
+		{"Cm7", true, "Cmin7"},
+		{"Cmin7", true, "Cmin7"},
+		{"F#dim7", true, "F#dim7"},
+		{"Bbsus4", true, "Bbsus4"},
+		{"Am/C", true, "Amin/C"},
+		{"G(add9)", true, "G(add9)"},
+		{"", false, ""},
+		{"Hello", false, ""},
+		{"Dam", false, ""},
+	}
+
+	for _, tc := range cases {
+		got, ok := Parse(tc.input)
+		if ok != tc.wantOK {
+			t.Errorf("Parse(%q) ok = %v, want %v", tc.input, ok, tc.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if s := got.String(); s != tc.wantString {
+			t.Errorf("Parse(%q).String() = %q, want %q", tc.input, s, tc.wantString)
+		}
+	}
+}
+
+// TestParseMajorSeventhDistinctFromDominant guards against the "maj"
+// in "maj7" being swallowed by the bare major-quality check before
+// the extension loop runs, which previously collapsed Cmaj7 and C7
+// into the same parsed chord.
+func TestParseMajorSeventhDistinctFromDominant(t *testing.T) {
+	maj7, ok := Parse("Cmaj7")
+	if !ok {
+		t.Fatalf("Parse(%q) failed", "Cmaj7")
+	}
+	dom7, ok := Parse("C7")
+	if !ok {
+		t.Fatalf("Parse(%q) failed", "C7")
+	}
+	if maj7.String() == dom7.String() {
+		t.Errorf("Cmaj7 and C7 parsed to the same chord: %q", maj7.String())
+	}
+}
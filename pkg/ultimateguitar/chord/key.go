@@ -0,0 +1,77 @@
+package chord
+
+import "math"
+
+// Krumhansl-Schmuckler key profiles: the perceived stability of each
+// pitch class relative to a tonic, for major and minor keys.
+var (
+	majorProfile = [12]float64{6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorProfile = [12]float64{6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+)
+
+// KeyEstimate is the best-fit key for a pitch-class histogram.
+type KeyEstimate struct {
+	Tonic       int // pitch class, 0 = C
+	Minor       bool
+	Correlation float64 // Pearson correlation against the winning profile rotation
+}
+
+// Name returns e.g. "C#m" or "G".
+func (e KeyEstimate) Name() string {
+	name := PitchClassName(e.Tonic)
+	if e.Minor {
+		return name + "m"
+	}
+	return name
+}
+
+// EstimateKey correlates histogram (a 12-bin pitch-class weight
+// vector, typically built from chord root occurrences weighted by
+// count or duration) against every rotation of the major and minor
+// Krumhansl-Schmuckler profiles, returning the best match.
+func EstimateKey(histogram [12]float64) KeyEstimate {
+	best := KeyEstimate{Correlation: math.Inf(-1)}
+	for tonic := 0; tonic < 12; tonic++ {
+		if c := correlate(histogram, rotate(majorProfile, tonic)); c > best.Correlation {
+			best = KeyEstimate{Tonic: tonic, Minor: false, Correlation: c}
+		}
+		if c := correlate(histogram, rotate(minorProfile, tonic)); c > best.Correlation {
+			best = KeyEstimate{Tonic: tonic, Minor: true, Correlation: c}
+		}
+	}
+	return best
+}
+
+// rotate shifts profile so its index 0 (the tonic's weight) lands at
+// index tonic, i.e. the profile's stability weights as heard relative
+// to a tonic other than C.
+func rotate(profile [12]float64, tonic int) [12]float64 {
+	var out [12]float64
+	for i := range out {
+		out[i] = profile[(i+12-tonic)%12]
+	}
+	return out
+}
+
+// correlate returns the Pearson correlation coefficient between two
+// equal-length vectors.
+func correlate(a, b [12]float64) float64 {
+	var sumA, sumB float64
+	for i := range a {
+		sumA += a[i]
+		sumB += b[i]
+	}
+	meanA, meanB := sumA/12, sumB/12
+
+	var cov, varA, varB float64
+	for i := range a {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
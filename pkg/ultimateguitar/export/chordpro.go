@@ -0,0 +1,81 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+)
+
+// ChordProExporter renders a tab as ChordPro, with UG's [tab]/[ch]
+// markup translated to {start_of_tab}/{start_of_chorus} directives
+// instead of being stripped.
+type ChordProExporter struct{}
+
+func (e *ChordProExporter) Name() string      { return "chordpro" }
+func (e *ChordProExporter) Extension() string { return "cho" }
+
+func (e *ChordProExporter) Render(tab ultimateguitar.TabResult, w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "{title: %s}\n{artist: %s}\n", tab.SongName, tab.ArtistName); err != nil {
+		return err
+	}
+	if tab.Capo > 0 {
+		if _, err := fmt.Fprintf(w, "{capo: %d}\n", tab.Capo); err != nil {
+			return err
+		}
+	}
+
+	for _, section := range ParseSections(tab.Content) {
+		switch section.Kind {
+		case SectionTab:
+			if _, err := fmt.Fprintln(w, "{start_of_tab}"); err != nil {
+				return err
+			}
+			for _, line := range section.Lines {
+				if _, err := fmt.Fprintln(w, line); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintln(w, "{end_of_tab}"); err != nil {
+				return err
+			}
+		case SectionChord:
+			if _, err := fmt.Fprintln(w, "{start_of_chorus}"); err != nil {
+				return err
+			}
+			for _, line := range section.Lines {
+				if err := writeChordProLine(w, line); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintln(w, "{end_of_chorus}"); err != nil {
+				return err
+			}
+		default:
+			for _, line := range section.Lines {
+				if _, err := fmt.Fprintln(w, line); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeChordProLine converts UG's [ch]C[/ch] markers to ChordPro's
+// native inline [C] chord notation.
+func writeChordProLine(w io.Writer, line string) error {
+	for _, tok := range SplitChordLyricLine(line) {
+		if tok.Chord != "" {
+			if _, err := fmt.Fprintf(w, "[%s]", tok.Chord); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := io.WriteString(w, tok.Text); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
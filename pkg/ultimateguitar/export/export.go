@@ -0,0 +1,64 @@
+// Package export provides pluggable renderers that turn a scraped
+// ultimateguitar.TabResult into a specific chord/tab file format
+// (ChordPro, OnSong, plain text, HTML, PDF, ...).
+package export
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+)
+
+// Exporter renders a single tab into a specific output format.
+type Exporter interface {
+	// Name is the short, flag-friendly identifier for this exporter,
+	// e.g. "chordpro", "onsong", "text", "html", "pdf".
+	Name() string
+	// Extension is the file extension (without the leading dot) used
+	// when writing the rendered output to disk.
+	Extension() string
+	// Render writes tab, formatted for this exporter, to w.
+	Render(tab ultimateguitar.TabResult, w io.Writer) error
+}
+
+var registry = map[string]Exporter{}
+
+// Register adds e to the set of known exporters, keyed by e.Name().
+// It panics if an exporter with the same name is already registered,
+// mirroring the stdlib's database/sql.Register behavior. Library
+// consumers can call Register to plug in custom formats before
+// resolving names with Get.
+func Register(e Exporter) {
+	name := e.Name()
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("export: Register called twice for format %q", name))
+	}
+	registry[name] = e
+}
+
+// Get looks up a registered exporter by name.
+func Get(name string) (Exporter, bool) {
+	e, ok := registry[name]
+	return e, ok
+}
+
+// Names returns the names of all registered exporters, sorted
+// alphabetically.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register(&ChordProExporter{})
+	Register(&OnSongExporter{})
+	Register(&TextExporter{})
+	Register(&HTMLExporter{})
+	Register(&PDFExporter{})
+}
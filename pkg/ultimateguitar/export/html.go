@@ -0,0 +1,107 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+)
+
+// HTMLExporter renders a tab as a themeable HTML document, wrapping
+// chords and tablature in CSS classes ("chord", "tab") rather than
+// baking in styling directly.
+type HTMLExporter struct{}
+
+func (e *HTMLExporter) Name() string      { return "html" }
+func (e *HTMLExporter) Extension() string { return "html" }
+
+func (e *HTMLExporter) Render(tab ultimateguitar.TabResult, w io.Writer) error {
+	_, err := fmt.Fprintf(w, `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>%s - %s</title>
+</head>
+<body>
+<article class="tab">
+<h1 class="song">%s</h1>
+<h2 class="artist">%s</h2>
+`, html.EscapeString(tab.ArtistName), html.EscapeString(tab.SongName),
+		html.EscapeString(tab.SongName), html.EscapeString(tab.ArtistName))
+	if err != nil {
+		return err
+	}
+	if tab.Capo > 0 {
+		if _, err := fmt.Fprintf(w, "<p class=\"capo\">Capo: %d</p>\n", tab.Capo); err != nil {
+			return err
+		}
+	}
+
+	for _, section := range ParseSections(tab.Content) {
+		switch section.Kind {
+		case SectionTab:
+			if _, err := io.WriteString(w, "<pre class=\"tab\">"); err != nil {
+				return err
+			}
+			for i, line := range section.Lines {
+				if i > 0 {
+					if _, err := io.WriteString(w, "\n"); err != nil {
+						return err
+					}
+				}
+				if _, err := io.WriteString(w, html.EscapeString(line)); err != nil {
+					return err
+				}
+			}
+			if _, err := io.WriteString(w, "</pre>\n"); err != nil {
+				return err
+			}
+		case SectionChord:
+			if _, err := io.WriteString(w, "<p class=\"lyrics\">"); err != nil {
+				return err
+			}
+			for _, line := range section.Lines {
+				if err := writeHTMLChordLine(w, line); err != nil {
+					return err
+				}
+				if _, err := io.WriteString(w, "<br>\n"); err != nil {
+					return err
+				}
+			}
+			if _, err := io.WriteString(w, "</p>\n"); err != nil {
+				return err
+			}
+		default:
+			if _, err := io.WriteString(w, "<p class=\"text\">"); err != nil {
+				return err
+			}
+			for _, line := range section.Lines {
+				if _, err := io.WriteString(w, html.EscapeString(line)+"<br>\n"); err != nil {
+					return err
+				}
+			}
+			if _, err := io.WriteString(w, "</p>\n"); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = io.WriteString(w, "</article>\n</body>\n</html>\n")
+	return err
+}
+
+func writeHTMLChordLine(w io.Writer, line string) error {
+	for _, tok := range SplitChordLyricLine(line) {
+		if tok.Chord != "" {
+			if _, err := fmt.Fprintf(w, `<span class="chord">%s</span>`, html.EscapeString(tok.Chord)); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := io.WriteString(w, html.EscapeString(tok.Text)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
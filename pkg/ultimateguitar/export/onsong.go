@@ -0,0 +1,71 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+)
+
+// OnSongExporter renders a tab in OnSong's plain-text format: a
+// metadata header block followed by lyric lines with inline [Chord]
+// annotations, the same bracket convention OnSong shares with
+// ChordPro.
+type OnSongExporter struct{}
+
+func (e *OnSongExporter) Name() string      { return "onsong" }
+func (e *OnSongExporter) Extension() string { return "onsong" }
+
+func (e *OnSongExporter) Render(tab ultimateguitar.TabResult, w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%s\n%s\n", tab.SongName, tab.ArtistName); err != nil {
+		return err
+	}
+	if tab.Capo > 0 {
+		if _, err := fmt.Fprintf(w, "Capo: %d\n", tab.Capo); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+
+	for _, section := range ParseSections(tab.Content) {
+		switch section.Kind {
+		case SectionTab:
+			for _, line := range section.Lines {
+				if _, err := fmt.Fprintln(w, line); err != nil {
+					return err
+				}
+			}
+		case SectionChord:
+			for _, line := range section.Lines {
+				if err := writeOnSongLine(w, line); err != nil {
+					return err
+				}
+			}
+		default:
+			for _, line := range section.Lines {
+				if _, err := fmt.Fprintln(w, line); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func writeOnSongLine(w io.Writer, line string) error {
+	for _, tok := range SplitChordLyricLine(line) {
+		if tok.Chord != "" {
+			if _, err := fmt.Fprintf(w, "[%s]", tok.Chord); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := io.WriteString(w, tok.Text); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// PDFExporter renders a tab as one PDF document per song (a long song
+// continues onto further pages via gofpdf's automatic page breaks), using the
+// same chords-over-lyrics layout as TextExporter. It depends only on
+// a pure-Go PDF library so the binary stays cgo-free.
+type PDFExporter struct{}
+
+func (e *PDFExporter) Name() string      { return "pdf" }
+func (e *PDFExporter) Extension() string { return "pdf" }
+
+func (e *PDFExporter) Render(tab ultimateguitar.TabResult, w io.Writer) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 8, tab.SongName, "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.CellFormat(0, 6, tab.ArtistName, "", 1, "L", false, 0, "")
+	if tab.Capo > 0 {
+		pdf.CellFormat(0, 6, "Capo: "+strconv.Itoa(tab.Capo), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	pdf.SetFont("Courier", "", 10)
+	for _, section := range ParseSections(tab.Content) {
+		for _, line := range section.Lines {
+			if section.Kind == SectionChord {
+				chordLine, lyricLine := alignChordLyricLine(line)
+				if chordLine != "" {
+					pdf.CellFormat(0, 4, chordLine, "", 1, "L", false, 0, "")
+				}
+				pdf.CellFormat(0, 4, lyricLine, "", 1, "L", false, 0, "")
+				continue
+			}
+			pdf.CellFormat(0, 4, line, "", 1, "L", false, 0, "")
+		}
+	}
+
+	return pdf.Output(w)
+}
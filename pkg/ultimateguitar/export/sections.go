@@ -0,0 +1,102 @@
+package export
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SectionKind classifies a contiguous run of lines within a tab's raw
+// content, based on the UG markup that wraps them.
+type SectionKind int
+
+const (
+	// SectionPlain is free text with no tab or inline-chord markup
+	// (section headers, blank lines, comments).
+	SectionPlain SectionKind = iota
+	// SectionTab is a verbatim tablature block, delimited by UG's
+	// [tab]...[/tab] markers.
+	SectionTab
+	// SectionChord is a block of lyric lines carrying inline chords
+	// via UG's [ch]...[/ch] markers.
+	SectionChord
+)
+
+// Section is a contiguous run of lines sharing the same Kind.
+type Section struct {
+	Kind  SectionKind
+	Lines []string
+}
+
+var (
+	tabOpenRegex  = regexp.MustCompile(`\[tab\]`)
+	tabCloseRegex = regexp.MustCompile(`\[/tab\]`)
+	chordRegex    = regexp.MustCompile(`\[ch\](.*?)\[/ch\]`)
+)
+
+// ParseSections splits a tab's raw Content into Sections, preserving
+// the distinction between [tab] blocks and [ch]-annotated lyric lines
+// instead of stripping the markers outright.
+func ParseSections(content string) []Section {
+	var sections []Section
+	inTab := false
+
+	appendLine := func(kind SectionKind, line string) {
+		if len(sections) > 0 && sections[len(sections)-1].Kind == kind {
+			last := &sections[len(sections)-1]
+			last.Lines = append(last.Lines, line)
+			return
+		}
+		sections = append(sections, Section{Kind: kind, Lines: []string{line}})
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case inTab:
+			if tabCloseRegex.MatchString(line) {
+				inTab = false
+				line = tabCloseRegex.ReplaceAllString(line, "")
+			}
+			appendLine(SectionTab, line)
+		case tabOpenRegex.MatchString(line):
+			inTab = true
+			line = tabOpenRegex.ReplaceAllString(line, "")
+			if tabCloseRegex.MatchString(line) {
+				inTab = false
+				line = tabCloseRegex.ReplaceAllString(line, "")
+			}
+			appendLine(SectionTab, line)
+		case chordRegex.MatchString(line):
+			appendLine(SectionChord, line)
+		default:
+			appendLine(SectionPlain, line)
+		}
+	}
+	return sections
+}
+
+// ChordLyricToken is one piece of a [ch]-annotated line: either a
+// chord name (Chord != "") or a run of plain lyric text.
+type ChordLyricToken struct {
+	Chord string
+	Text  string
+}
+
+// SplitChordLyricLine breaks a line containing [ch]...[/ch] markers
+// into an ordered sequence of chord and text tokens, e.g.
+// "[ch]G[/ch]Amazing [ch]C[/ch]grace" becomes
+// [{G ""} {"" "Amazing "} {C ""} {"" "grace"}].
+func SplitChordLyricLine(line string) []ChordLyricToken {
+	var tokens []ChordLyricToken
+	last := 0
+	for _, loc := range chordRegex.FindAllStringSubmatchIndex(line, -1) {
+		if loc[0] > last {
+			tokens = append(tokens, ChordLyricToken{Text: line[last:loc[0]]})
+		}
+		tokens = append(tokens, ChordLyricToken{Chord: line[loc[2]:loc[3]]})
+		last = loc[1]
+	}
+	if last < len(line) {
+		tokens = append(tokens, ChordLyricToken{Text: line[last:]})
+	}
+	return tokens
+}
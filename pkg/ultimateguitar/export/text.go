@@ -0,0 +1,71 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+)
+
+// TextExporter renders a tab as plain text, converting [ch] inline
+// chord markers into a chord line positioned above the lyric line
+// they annotate, the classic "chords-over-lyrics" songbook layout.
+type TextExporter struct{}
+
+func (e *TextExporter) Name() string      { return "text" }
+func (e *TextExporter) Extension() string { return "txt" }
+
+func (e *TextExporter) Render(tab ultimateguitar.TabResult, w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%s - %s\n", tab.ArtistName, tab.SongName); err != nil {
+		return err
+	}
+	if tab.Capo > 0 {
+		if _, err := fmt.Fprintf(w, "Capo: %d\n", tab.Capo); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+
+	for _, section := range ParseSections(tab.Content) {
+		for _, line := range section.Lines {
+			if section.Kind != SectionChord {
+				if _, err := fmt.Fprintln(w, line); err != nil {
+					return err
+				}
+				continue
+			}
+			chordLine, lyricLine := alignChordLyricLine(line)
+			if strings.TrimSpace(chordLine) != "" {
+				if _, err := fmt.Fprintln(w, chordLine); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintln(w, lyricLine); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// alignChordLyricLine converts "[ch]G[/ch]Amazing [ch]C[/ch]grace"
+// into a chord line and a lyric line, with each chord positioned
+// above the lyric column it precedes.
+func alignChordLyricLine(line string) (chordLine, lyricLine string) {
+	var chords, lyrics strings.Builder
+	for _, tok := range SplitChordLyricLine(line) {
+		if tok.Chord != "" {
+			pad := lyrics.Len() - chords.Len()
+			if pad > 0 {
+				chords.WriteString(strings.Repeat(" ", pad))
+			}
+			chords.WriteString(tok.Chord)
+			continue
+		}
+		lyrics.WriteString(tok.Text)
+	}
+	return chords.String(), lyrics.String()
+}
@@ -0,0 +1,77 @@
+// Package filter decides whether a scraped tab should be written out,
+// based on predicates over its type, rating, or artist.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+)
+
+// Filter is a set of predicates a tab must satisfy to be written.
+// A zero-value Filter matches everything.
+type Filter struct {
+	// Type restricts to a single tab type ("chords", "tabs", "bass",
+	// "ukulele"). Empty matches any type.
+	Type string
+	// MinRating requires tab.Rating >= MinRating. Zero means no
+	// minimum.
+	MinRating float64
+	// ArtistPattern, if set, requires tab.ArtistName to match it.
+	ArtistPattern *regexp.Regexp
+}
+
+// Match reports whether tab satisfies every predicate set on f.
+func (f Filter) Match(tab ultimateguitar.TabResult) bool {
+	if f.Type != "" && !strings.EqualFold(tab.Type, f.Type) {
+		return false
+	}
+	if f.MinRating > 0 && tab.Rating < f.MinRating {
+		return false
+	}
+	if f.ArtistPattern != nil && !f.ArtistPattern.MatchString(tab.ArtistName) {
+		return false
+	}
+	return true
+}
+
+// Parse parses a comma-separated "key=value" expression, e.g.
+// "type=bass,min-rating=4,artist=^Metallica$", into a Filter. This is
+// the format used by a config file's collections: section. Recognized
+// keys: type, min-rating, artist.
+func Parse(expr string) (Filter, error) {
+	var f Filter
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Filter{}, fmt.Errorf("filter: invalid expression %q: expected key=value", part)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "type":
+			f.Type = value
+		case "min-rating":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return Filter{}, fmt.Errorf("filter: invalid min-rating %q: %w", value, err)
+			}
+			f.MinRating = n
+		case "artist":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return Filter{}, fmt.Errorf("filter: invalid artist pattern %q: %w", value, err)
+			}
+			f.ArtistPattern = re
+		default:
+			return Filter{}, fmt.Errorf("filter: unknown key %q", key)
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,47 @@
+// Package index writes a per-run manifest of what was saved where,
+// so downstream tools (playlist apps, sync scripts) can consume a
+// scraped library without re-deriving its layout.
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Entry records where a single tab ended up.
+type Entry struct {
+	Artist string   `json:"artist"`
+	Song   string   `json:"song"`
+	Root   string   `json:"root"`
+	Files  []string `json:"files"`
+}
+
+// WriteJSON writes entries as a JSON array to path.
+func WriteJSON(path string, entries []Entry) error {
+	raw, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("index: encoding %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		return fmt.Errorf("index: writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteM3U writes entries as an extended M3U playlist to path, one
+// #EXTINF line plus one path line per file across all entries.
+func WriteM3U(path string, entries []Entry) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	for _, e := range entries {
+		for _, f := range e.Files {
+			fmt.Fprintf(&b, "#EXTINF:-1,%s - %s\n%s\n", e.Artist, e.Song, f)
+		}
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("index: writing %s: %w", path, err)
+	}
+	return nil
+}
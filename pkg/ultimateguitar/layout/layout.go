@@ -0,0 +1,45 @@
+// Package layout renders a user-configurable path template (e.g.
+// "{artist}/{artist} - {song} ({type}).crd") against a tab's
+// metadata, so output libraries can be organized however the caller
+// wants instead of a single hardcoded naming scheme.
+package layout
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+)
+
+// Default is the template used when the caller doesn't configure one.
+const Default = "{artist} - {song}"
+
+var unsafePathChars = regexp.MustCompile(`[<>:"/\\|?*]`)
+
+// sanitize strips characters that are invalid in file/directory names
+// on common filesystems, so template variables can never escape the
+// output root or collide with path separators.
+func sanitize(s string) string {
+	s = strings.TrimSpace(unsafePathChars.ReplaceAllString(s, ""))
+	if s == "." || s == ".." {
+		return ""
+	}
+	return s
+}
+
+// Render substitutes the template's {variable} placeholders with
+// sanitized values from tab. Supported variables: artist, song, capo,
+// tuning, type, difficulty, rating.
+func Render(template string, tab ultimateguitar.TabResult) string {
+	replacer := strings.NewReplacer(
+		"{artist}", sanitize(tab.ArtistName),
+		"{song}", sanitize(tab.SongName),
+		"{capo}", fmt.Sprintf("%d", tab.Capo),
+		"{tuning}", sanitize(tab.Tuning),
+		"{type}", sanitize(tab.Type),
+		"{difficulty}", sanitize(tab.Difficulty),
+		"{rating}", fmt.Sprintf("%.1f", tab.Rating),
+	)
+	return replacer.Replace(template)
+}
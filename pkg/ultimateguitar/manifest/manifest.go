@@ -0,0 +1,126 @@
+// Package manifest tracks which tabs have already been fetched to a
+// local output directory, so repeat runs can skip anything that
+// hasn't changed on Ultimate Guitar since the last sync.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+var tabsBucket = []byte("tabs")
+
+// Entry is the manifest record for a single previously-fetched tab.
+// ArtistName, SongName, and Files are kept alongside the hash/version
+// bookkeeping so a tab's place in the index can be reconstructed on a
+// later run without re-fetching it just to re-derive where it went.
+type Entry struct {
+	ID          string    `json:"id"`
+	ArtistName  string    `json:"artist_name"`
+	SongName    string    `json:"song_name"`
+	ContentHash string    `json:"content_hash"`
+	Version     int       `json:"version"`
+	Files       []string  `json:"files"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// Manifest is a small embedded database recording one Entry per tab
+// ID, stored inside a get_all output directory (get_all keeps it in
+// its primary output root as ".ug-scraper-manifest.db").
+type Manifest struct {
+	db *bbolt.DB
+}
+
+// Open creates or opens the manifest database at path.
+func Open(path string) (*Manifest, error) {
+	db, err := bbolt.Open(path, 0644, nil)
+	if err != nil {
+		return nil, fmt.Errorf("manifest: opening %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(tabsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("manifest: initializing %s: %w", path, err)
+	}
+	return &Manifest{db: db}, nil
+}
+
+// Close releases the underlying database file.
+func (m *Manifest) Close() error {
+	return m.db.Close()
+}
+
+// Get returns the recorded entry for id, if any.
+func (m *Manifest) Get(id string) (Entry, bool, error) {
+	var entry Entry
+	found := false
+	err := m.db.View(func(tx *bbolt.Tx) error {
+		raw := tx.Bucket(tabsBucket).Get([]byte(id))
+		if raw == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(raw, &entry)
+	})
+	return entry, found, err
+}
+
+// Put records or replaces the entry for entry.ID.
+func (m *Manifest) Put(entry Entry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("manifest: encoding entry %s: %w", entry.ID, err)
+	}
+	return m.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tabsBucket).Put([]byte(entry.ID), raw)
+	})
+}
+
+// Delete removes the entry for id, if present.
+func (m *Manifest) Delete(id string) error {
+	return m.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tabsBucket).Delete([]byte(id))
+	})
+}
+
+// All returns every recorded entry, keyed by ID.
+func (m *Manifest) All() (map[string]Entry, error) {
+	entries := make(map[string]Entry)
+	err := m.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tabsBucket).ForEach(func(k, v []byte) error {
+			var entry Entry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("decoding entry %s: %w", k, err)
+			}
+			entries[string(k)] = entry
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// Prune deletes every recorded entry whose ID is not in seen, and
+// returns the IDs that were removed.
+func (m *Manifest) Prune(seen map[string]bool) ([]string, error) {
+	all, err := m.All()
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for id := range all {
+		if seen[id] {
+			continue
+		}
+		if err := m.Delete(id); err != nil {
+			return removed, fmt.Errorf("manifest: pruning %s: %w", id, err)
+		}
+		removed = append(removed, id)
+	}
+	return removed, nil
+}
@@ -0,0 +1,103 @@
+package sync
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+)
+
+// PoolOptions configures FetchPool's concurrency, pacing and retry
+// behavior.
+type PoolOptions struct {
+	// Workers is the number of tabs fetched concurrently. Zero means
+	// runtime.NumCPU(), matching audioc's worker-pool default.
+	Workers int
+	// RateLimit, if non-zero, is the minimum interval between any two
+	// fetch starts, shared across all workers, so a large library
+	// doesn't hammer UG's API. Retries are paced by Backoff only.
+	RateLimit time.Duration
+	// MaxRetries is how many additional attempts a failing fetch
+	// gets before it's reported as an error.
+	MaxRetries int
+	// Backoff is the base delay before a retry; it doubles after
+	// each failed attempt (1x, 2x, 4x, ...).
+	Backoff time.Duration
+}
+
+// FetchPool fetches each listing's full TabResult using opts.Workers
+// concurrent workers against UG's API, rate-limiting and retrying with
+// exponential backoff per opts. It returns the successfully fetched
+// tabs, plus one error per listing that still failed after retries
+// were exhausted; neither is in any particular order.
+func FetchPool(listings []ultimateguitar.TabListing, opts PoolOptions, fetch func(ultimateguitar.TabListing) (ultimateguitar.TabResult, error)) ([]ultimateguitar.TabResult, []error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan ultimateguitar.TabListing)
+	var (
+		mu      sync.Mutex
+		results []ultimateguitar.TabResult
+		errs    []error
+		wg      sync.WaitGroup
+		ticker  *time.Ticker
+	)
+	if opts.RateLimit > 0 {
+		ticker = time.NewTicker(opts.RateLimit)
+		defer ticker.Stop()
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for listing := range jobs {
+				if ticker != nil {
+					<-ticker.C
+				}
+				tab, err := fetchWithRetry(listing, opts, fetch)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					results = append(results, tab)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, listing := range listings {
+		jobs <- listing
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, errs
+}
+
+func fetchWithRetry(listing ultimateguitar.TabListing, opts PoolOptions, fetch func(ultimateguitar.TabListing) (ultimateguitar.TabResult, error)) (ultimateguitar.TabResult, error) {
+	var (
+		tab ultimateguitar.TabResult
+		err error
+	)
+	backoff := opts.Backoff
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		if tab, err = fetch(listing); err == nil {
+			return tab, nil
+		}
+		if attempt == opts.MaxRetries {
+			break
+		}
+		if backoff > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return tab, fmt.Errorf("tab %s: %w", strconv.Itoa(listing.Tab.ID), err)
+}
@@ -0,0 +1,94 @@
+// Package sync diffs UG's saved-tab listing against a
+// manifest.Manifest so repeat runs only download what's new or
+// changed, instead of re-fetching every saved tab's full content on
+// every run.
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar"
+	"github.com/Pilfer/ultimate-guitar-scraper/pkg/ultimateguitar/manifest"
+)
+
+// ListPlan is the result of diffing the remote saved-tab listing
+// against the manifest, before any tab's full content has been
+// downloaded: which listings need a full fetch, and which
+// previously-seen IDs are no longer in the remote list.
+type ListPlan struct {
+	ToFetch []ultimateguitar.TabListing
+	Removed []string
+}
+
+// ContentHash returns a stable hash of a tab's content, stored in the
+// manifest alongside Version. DiffListings decides what to fetch from
+// Version alone; the recorded hash is not compared there.
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// DiffListings compares listings (UG's lightweight "my tabs" response,
+// which carries each tab's ID and version but not its full content)
+// against m. A listing is scheduled for fetching when its tab is new,
+// its version changed since last recorded, force is true, or it was
+// last seen before since. Manifest entries for IDs no longer present
+// in listings are reported in Removed (the caller decides whether to
+// prune them).
+func DiffListings(m *manifest.Manifest, listings []ultimateguitar.TabListing, since time.Time, force bool) (ListPlan, error) {
+	var plan ListPlan
+	seen := make(map[string]bool, len(listings))
+
+	for _, listing := range listings {
+		id := strconv.Itoa(listing.Tab.ID)
+		seen[id] = true
+
+		entry, ok, err := m.Get(id)
+		if err != nil {
+			return plan, err
+		}
+
+		needsFetch := force || !ok
+		if ok {
+			if entry.Version != listing.Tab.Version {
+				needsFetch = true
+			}
+			if !since.IsZero() && entry.LastSeen.Before(since) {
+				needsFetch = true
+			}
+		}
+
+		if needsFetch {
+			plan.ToFetch = append(plan.ToFetch, listing)
+		}
+	}
+
+	existing, err := m.All()
+	if err != nil {
+		return plan, err
+	}
+	for id := range existing {
+		if !seen[id] {
+			plan.Removed = append(plan.Removed, id)
+		}
+	}
+
+	return plan, nil
+}
+
+// Record upserts the manifest entry for tab once it's been fetched and
+// written to files, stamping LastSeen with now.
+func Record(m *manifest.Manifest, tab ultimateguitar.TabResult, files []string, now time.Time) error {
+	return m.Put(manifest.Entry{
+		ID:          strconv.Itoa(tab.ID),
+		ArtistName:  tab.ArtistName,
+		SongName:    tab.SongName,
+		ContentHash: ContentHash(tab.Content),
+		Version:     tab.Version,
+		Files:       files,
+		LastSeen:    now,
+	})
+}